@@ -0,0 +1,108 @@
+package redis
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// newTestClient starts a miniredis instance and returns a go-redis client
+// connected to it. The miniredis server is closed when the test ends.
+func newTestClient(t *testing.T) goredis.UniversalClient {
+	t.Helper()
+
+	mr := miniredis.RunT(t)
+	return goredis.NewClient(&goredis.Options{Addr: mr.Addr()})
+}
+
+func TestCachedScriptRunReloadsOnNoscript(t *testing.T) {
+	client := newTestClient(t)
+	ctx := context.Background()
+
+	const script = `return ARGV[1]`
+
+	var c cachedScript
+
+	// Prime the cache with a SHA the server has never seen, simulating the
+	// script having been evicted (for example after a server restart).
+	c.sha = "0000000000000000000000000000000000000000"
+
+	reply, err := c.run(ctx, client, script, nil, "ok")
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if reply != "ok" {
+		t.Errorf("run returned %v, expected %q", reply, "ok")
+	}
+
+	if c.sha == "0000000000000000000000000000000000000000" {
+		t.Errorf("cachedScript did not update its SHA after a NOSCRIPT reload")
+	}
+
+	// The now-cached SHA must work on a second call without reloading.
+	reply, err = c.run(ctx, client, script, nil, "again")
+	if err != nil {
+		t.Fatalf("run after reload: %v", err)
+	}
+	if reply != "again" {
+		t.Errorf("run after reload returned %v, expected %q", reply, "again")
+	}
+}
+
+func TestNotifyReceiveGroupResetsGroupReadyAfterNogroup(t *testing.T) {
+	client := newTestClient(t)
+	ctx := context.Background()
+
+	r := &Redis{
+		client:   client,
+		group:    "icc",
+		consumer: "test-consumer",
+	}
+
+	if err := r.createNotifyGroup(ctx); err != nil {
+		t.Fatalf("createNotifyGroup: %v", err)
+	}
+	r.groupReady = true
+
+	// Destroy the group out from under r, simulating the transient
+	// failure/eviction notifyReceiveGroup has to recover from: a NOGROUP
+	// error even though groupReady still says the group exists.
+	if err := client.XGroupDestroy(ctx, notifyKey, r.group).Err(); err != nil {
+		t.Fatalf("xgroup destroy: %v", err)
+	}
+
+	if _, err := r.notifyReceiveGroup(ctx); err == nil {
+		t.Fatal("notifyReceiveGroup succeeded, expected a NOGROUP error")
+	}
+	if r.groupReady {
+		t.Fatal("groupReady still true after a NOGROUP error, expected a reset so the next call recreates the group")
+	}
+
+	// notifyReceiveGroup recreates the group with "$", so it only sees
+	// messages published after that point; publish after the reset, not
+	// before, or the read below would block forever waiting for a message
+	// the new group was never going to see. The test therefore recreates the
+	// group itself, as the next notifyReceiveGroup call would with
+	// groupReady false, instead of leaving that to notifyReceiveGroup.
+	if err := r.createNotifyGroup(ctx); err != nil {
+		t.Fatalf("createNotifyGroup: %v", err)
+	}
+	r.groupReady = true
+
+	if err := r.NotifyPublish([]byte("hello")); err != nil {
+		t.Fatalf("NotifyPublish: %v", err)
+	}
+
+	msg, err := r.notifyReceiveGroup(ctx)
+	if err != nil {
+		t.Fatalf("notifyReceiveGroup after recreate: %v", err)
+	}
+	if string(msg) != "hello" {
+		t.Errorf("notifyReceiveGroup returned %q, expected %q", msg, "hello")
+	}
+	if !r.groupReady {
+		t.Error("groupReady false after a successful recreate and read")
+	}
+}